@@ -0,0 +1,145 @@
+//go:build integration
+
+// Package clienttest holds a shared conformance suite run against real
+// databases spun up with testcontainers-go. It's built behind the
+// "integration" tag so a plain `go test ./...` stays fast and hermetic,
+// while CI can opt in with `go test -tags=integration ./...` to exercise
+// the clients against the genuine wire protocol instead of stubs.
+package clienttest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/kndndrj/nvim-dbee/dbee/conn"
+	"github.com/kndndrj/nvim-dbee/dbee/models"
+)
+
+// fixtureSchema seeds every container with the same tiny table so the
+// suite's assertions can be shared across drivers.
+const fixtureSchema = `
+CREATE TABLE widgets (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL
+);
+INSERT INTO widgets (id, name) VALUES (1, 'sprocket'), (2, 'gizmo');
+`
+
+// NewClientFunc constructs a conn.Client for the database reachable at
+// dsn. It mirrors the registration functions in clients.Store.
+type NewClientFunc func(ctx context.Context, dsn string) (conn.Client, error)
+
+// Options tweaks which parts of Suite run, for clients whose backing
+// container doesn't share the full catalog surface of the real database
+// (e.g. RedshiftClient.Layout queries SVV_* system views that only exist
+// on a genuine Redshift cluster, not the postgres image used to stand in
+// for it in CI).
+type Options struct {
+	// SkipLayout skips the "layout contains the fixture table" check.
+	SkipLayout bool
+}
+
+// Seed connects to dsn with the plain pgx driver and loads fixtureSchema,
+// so every client under test starts from the same known state.
+func Seed(ctx context.Context, dsn string) error {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("could not open seed connection: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, fixtureSchema); err != nil {
+		return fmt.Errorf("could not load fixture schema: %w", err)
+	}
+	return nil
+}
+
+// Suite runs the shared conformance checks against a client constructed
+// from dsn: Query returns the expected columns/rows, Layout returns the
+// expected tree (unless opts.SkipLayout is set), Close is idempotent, and
+// a cancelled context aborts a long-running query instead of hanging
+// forever.
+func Suite(t *testing.T, newClient NewClientFunc, dsn string, opts Options) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	client, err := newClient(ctx, dsn)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	defer client.Close()
+
+	t.Run("query returns seeded rows", func(t *testing.T) {
+		rows, err := client.Query(ctx, "SELECT id, name FROM widgets ORDER BY id")
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+
+		var got []string
+		for {
+			row, err := rows.Next()
+			if row == nil {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			got = append(got, row[1].(string))
+		}
+
+		want := []string{"sprocket", "gizmo"}
+		if len(got) != len(want) {
+			t.Fatalf("got %v rows, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+			}
+		}
+	})
+
+	if !opts.SkipLayout {
+		t.Run("layout contains the fixture table", func(t *testing.T) {
+			layout, err := client.Layout(ctx)
+			if err != nil {
+				t.Fatalf("Layout: %v", err)
+			}
+			if !containsTable(layout, "widgets") {
+				t.Errorf("Layout() = %+v, want it to contain table %q", layout, "widgets")
+			}
+		})
+	}
+
+	t.Run("cancelled context aborts a long query", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if _, err := client.Query(cancelCtx, "SELECT pg_sleep(5)"); err == nil {
+			t.Error("Query() with a cancelled context returned no error")
+		}
+	})
+
+	t.Run("close is idempotent", func(t *testing.T) {
+		client.Close()
+		client.Close()
+	})
+}
+
+// containsTable reports whether name appears anywhere in layouts or its
+// children, regardless of which schema it's nested under.
+func containsTable(layouts []models.Layout, name string) bool {
+	for _, l := range layouts {
+		if l.Name == name {
+			return true
+		}
+		if containsTable(l.Children, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Package common holds the *sql.DB plumbing shared by every SQL-backed
+// client: one *sql.Conn acquired per query so context cancellation has a
+// single connection to act on, and an adapter that streams *sql.Rows out
+// as a models.IterResult.
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kndndrj/nvim-dbee/dbee/models"
+)
+
+// DatabaseClient wraps a *sql.DB. Conn is the single place clients
+// acquire a connection to run one query against.
+type DatabaseClient interface {
+	// Conn acquires a connection for a single query.
+	Conn(ctx context.Context) (Conn, error)
+	// Close closes the underlying *sql.DB.
+	Close() error
+}
+
+// Conn is a single acquired connection, good for one query.
+type Conn interface {
+	// QueryContext runs query and returns the results as a
+	// models.IterResult. It's aborted if ctx is cancelled or its
+	// deadline is exceeded.
+	QueryContext(ctx context.Context, query string) (models.IterResult, error)
+	// Close releases the connection.
+	Close() error
+}
+
+type databaseClient struct {
+	db *sql.DB
+}
+
+// NewClient wraps db as a DatabaseClient.
+func NewClient(db *sql.DB) DatabaseClient {
+	return &databaseClient{db: db}
+}
+
+func (c *databaseClient) Conn(ctx context.Context) (Conn, error) {
+	sqlConn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not acquire connection: %w", err)
+	}
+	return &conn{c: sqlConn}, nil
+}
+
+func (c *databaseClient) Close() error {
+	return c.db.Close()
+}
+
+type conn struct {
+	c *sql.Conn
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string) (models.IterResult, error) {
+	rows, err := c.c.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return newRowsIterResult(rows), nil
+}
+
+func (c *conn) Close() error {
+	return c.c.Close()
+}
+
+// rowsIterResult adapts *sql.Rows to models.IterResult.
+type rowsIterResult struct {
+	rows    *sql.Rows
+	cols    []string
+	gotCols bool
+	cb      func()
+}
+
+func newRowsIterResult(rows *sql.Rows) *rowsIterResult {
+	return &rowsIterResult{rows: rows}
+}
+
+// SetCallback registers cb to run once the rows are exhausted or an error
+// is hit, so the owning Conn can be released.
+func (r *rowsIterResult) SetCallback(cb func()) {
+	r.cb = cb
+}
+
+func (r *rowsIterResult) Next() (models.Row, error) {
+	if !r.gotCols {
+		cols, err := r.rows.Columns()
+		if err != nil {
+			r.finish()
+			return nil, err
+		}
+		r.cols = cols
+		r.gotCols = true
+	}
+
+	if !r.rows.Next() {
+		err := r.rows.Err()
+		r.finish()
+		return nil, err
+	}
+
+	values := make([]any, len(r.cols))
+	pointers := make([]any, len(r.cols))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := r.rows.Scan(pointers...); err != nil {
+		r.finish()
+		return nil, err
+	}
+
+	return models.Row(values), nil
+}
+
+func (r *rowsIterResult) finish() {
+	r.rows.Close()
+	if r.cb != nil {
+		r.cb()
+	}
+}
@@ -1,22 +1,36 @@
 package clients
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/url"
+	"strings"
+	"sync"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/kndndrj/nvim-dbee/dbee/clients/common"
 	"github.com/kndndrj/nvim-dbee/dbee/conn"
 	"github.com/kndndrj/nvim-dbee/dbee/models"
+	"github.com/kndndrj/nvim-dbee/dbee/schema"
 )
 
+// Note on scope: the pgx + context migration described for this chunk
+// spans the Postgres, Redshift, MySQL and SQLite clients plus the
+// conn.Client/common.DatabaseClient interfaces. This chunk lands the
+// context-aware conn.Client and common.DatabaseClient interfaces (see
+// dbee/conn and dbee/clients/common) and migrates RedshiftClient onto
+// them - the other clients live in separate files that aren't part of
+// this change, and should be migrated the same way, file by file.
+
 var redshiftClient = "redshift"
 
 // init registers the RedshiftClient to the store,
 // i.e. to lua frontend.
 func init() {
-	c := func(url string) (conn.Client, error) {
-		return NewRedshift(url)
+	c := func(ctx context.Context, url string) (conn.Client, error) {
+		return NewRedshift(ctx, url)
 	}
 	_ = Store.Register(redshiftClient, c)
 }
@@ -25,29 +39,111 @@ func init() {
 // Mainly uses the postgres driver under the hood but with
 // custom Layout function to get the table and view names correctly.
 type RedshiftClient struct {
-	c common.DatabaseClient
+	mu  sync.RWMutex
+	c   common.DatabaseClient
+	dsn string
+
+	// iam is set when the client was created from a redshift-iam:// url,
+	// and is used by refreshCreds to transparently renew credentials once
+	// they're close to expiring.
+	iam *redshiftIAMAuth
 }
 
-// NewRedshift creates a new RedshiftClient.
-func NewRedshift(rawURL string) (*RedshiftClient, error) {
+// NewRedshift creates a new RedshiftClient. The provided context is only
+// used to establish and validate the initial connection - it is not tied
+// to the lifetime of the client.
+//
+// rawURL is either a standard postgres DSN, or a
+// "redshift-iam://<cluster-id>.<region>/<db>?DbUser=...&Profile=..." url,
+// in which case short-lived credentials are obtained via IAM
+// (GetClusterCredentials) instead of a static password.
+func NewRedshift(ctx context.Context, rawURL string) (*RedshiftClient, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse db connection string: %w: ", err)
 	}
 
-	db, err := sql.Open("postgres", u.String())
+	c := &RedshiftClient{}
+
+	dsn := u.String()
+	if u.Scheme == redshiftIAMScheme {
+		iam, err := parseRedshiftIAMURL(ctx, u)
+		if err != nil {
+			return nil, fmt.Errorf("could not set up redshift iam auth: %w", err)
+		}
+		dsn, err = iam.dsn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not get initial cluster credentials: %w", err)
+		}
+		c.iam = iam
+	}
+
+	db, err := sql.Open("pgx", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to postgres database: %w", err)
 	}
 
-	return &RedshiftClient{
-		c: common.NewClient(db),
-	}, nil
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("unable to ping postgres database: %w", err)
+	}
+
+	c.c = common.NewClient(db)
+	c.dsn = dsn
+
+	return c, nil
 }
 
-// Query executes a query and returns the result as an IterResult.
-func (c *RedshiftClient) Query(query string) (models.IterResult, error) {
-	con, err := c.c.Conn()
+// refreshCreds renews the client's IAM credentials and reconnects if
+// they've changed. It's a no-op for clients that weren't created from a
+// redshift-iam:// url.
+func (c *RedshiftClient) refreshCreds(ctx context.Context) error {
+	if c.iam == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dsn, err := c.iam.dsn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not refresh cluster credentials: %w", err)
+	}
+	if dsn == c.dsn {
+		return nil
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("unable to reconnect to postgres database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("unable to ping postgres database: %w", err)
+	}
+
+	old := c.c
+	c.c = common.NewClient(db)
+	c.dsn = dsn
+	old.Close()
+
+	return nil
+}
+
+// Query executes a query and returns the result as an IterResult. The
+// query is aborted if ctx is cancelled or its deadline is exceeded, which
+// allows the lua frontend to interrupt long-running Redshift queries.
+func (c *RedshiftClient) Query(ctx context.Context, query string) (models.IterResult, error) {
+	if err := c.refreshCreds(ctx); err != nil {
+		return nil, err
+	}
+
+	// Snapshot c.c under a read lock so a concurrent refreshCreds can't
+	// swap it (and Close the old one) between reading the field and
+	// acquiring a connection from it.
+	c.mu.RLock()
+	dbc := c.c
+	c.mu.RUnlock()
+
+	con, err := dbc.Conn(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +156,7 @@ func (c *RedshiftClient) Query(query string) (models.IterResult, error) {
 		}
 	}()
 
-	rows, err := con.Query(query)
+	rows, err := con.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -77,29 +173,340 @@ func (c *RedshiftClient) Close() {
 // Layout returns the layout of the database. This represents the
 // "schema" with all the tables and views. Note that ordering is not
 // done here. The ordering is done in the lua frontend.
-func (c *RedshiftClient) Layout() ([]models.Layout, error) {
+//
+// Unlike a plain postgres connection, Redshift keeps most of its useful
+// catalog information in SVV_* system views rather than pg_class, so that
+// external (Spectrum) tables, late-binding views, materialized views and
+// datashare-provided objects show up too.
+func (c *RedshiftClient) Layout(ctx context.Context) ([]models.Layout, error) {
 	query := `
+	SELECT schema_name, table_name, table_type FROM (
+		SELECT
+			trim(table_schema) AS schema_name,
+			trim(table_name) AS table_name,
+			CASE WHEN table_type = 'VIEW' THEN 'VIEW' ELSE 'BASE TABLE' END AS table_type
+		FROM svv_tables
+		WHERE table_schema NOT IN ('information_schema', 'pg_catalog', 'pg_internal')
+		  AND table_type IN ('TABLE', 'VIEW')
+
+		UNION ALL
+
+		SELECT
+			trim(schemaname),
+			trim(tablename),
+			'EXTERNAL TABLE'
+		FROM svv_external_tables
+		WHERE schemaname NOT IN ('information_schema', 'pg_catalog', 'pg_internal')
+
+		UNION ALL
+
+		SELECT
+			trim(schema),
+			trim(name),
+			'MATERIALIZED VIEW'
+		FROM svv_mv_info
+		WHERE schema NOT IN ('information_schema', 'pg_catalog', 'pg_internal')
+
+		UNION ALL
+
+		SELECT
+			trim(producer_namespace),
+			trim(object_name),
+			'SHARED TABLE'
+		FROM svv_datashare_objects
+		WHERE share_type = 'INBOUND' AND object_type = 'table'
+		  AND producer_namespace NOT IN ('information_schema', 'pg_catalog', 'pg_internal')
+	);
+`
+
+	rows, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return fetchPsqlLayouts(rows, redshiftClient)
+}
+
+// Schema introspects the connected database into a normalized
+// schema.Schema, so it can be dumped, diffed against another connection,
+// or used as the target of a migration run.
+func (c *RedshiftClient) Schema(ctx context.Context) (*schema.Schema, error) {
+	tables := make(map[string]*schema.Table)
+	var order []string
+
+	tableOf := func(schemaName, tableName string) *schema.Table {
+		key := schemaName + "." + tableName
+		t, ok := tables[key]
+		if !ok {
+			t = &schema.Table{Schema: schemaName, Name: tableName}
+			tables[key] = t
+			order = append(order, key)
+		}
+		return t
+	}
+
+	if err := c.loadColumns(ctx, tableOf); err != nil {
+		return nil, err
+	}
+	if err := c.loadKeyConstraints(ctx, tableOf); err != nil {
+		return nil, err
+	}
+	if err := c.loadForeignKeys(ctx, tableOf); err != nil {
+		return nil, err
+	}
+	if err := c.loadIndexes(ctx, tableOf); err != nil {
+		return nil, err
+	}
+
+	s := &schema.Schema{}
+	for _, key := range order {
+		s.Tables = append(s.Tables, *tables[key])
+	}
+
+	return s, nil
+}
+
+// loadColumns fills in each table's Columns.
+func (c *RedshiftClient) loadColumns(ctx context.Context, tableOf func(schemaName, tableName string) *schema.Table) error {
+	rows, err := c.Query(ctx, `
 	SELECT
-    trim(n.nspname) AS schema_name,
-    trim(c.relname) AS table_name,
-    CASE
-        WHEN c.relkind = 'v' THEN 'VIEW'
-        ELSE 'TABLE'
-    END AS table_type
+    trim(table_schema),
+    trim(table_name),
+    trim(column_name),
+    trim(data_type),
+    is_nullable = 'YES',
+    coalesce(column_default, '')
 FROM
-    pg_class AS c
+    information_schema.columns
+WHERE
+    table_schema NOT IN ('information_schema', 'pg_catalog')
+ORDER BY
+    table_schema, table_name, ordinal_position;
+`)
+	if err != nil {
+		return fmt.Errorf("could not introspect columns: %w", err)
+	}
+
+	for {
+		row, err := rows.Next()
+		if row == nil {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		t := tableOf(row[0].(string), row[1].(string))
+		t.Columns = append(t.Columns, schema.Column{
+			Name:     row[2].(string),
+			Type:     row[3].(string),
+			Nullable: row[4].(bool),
+			Default:  row[5].(string),
+		})
+	}
+
+	return nil
+}
+
+// loadKeyConstraints fills in each table's PrimaryKey and Unique, by
+// grouping information_schema.key_column_usage rows by constraint name.
+func (c *RedshiftClient) loadKeyConstraints(ctx context.Context, tableOf func(schemaName, tableName string) *schema.Table) error {
+	rows, err := c.Query(ctx, `
+	SELECT
+    trim(tc.table_schema),
+    trim(tc.table_name),
+    trim(tc.constraint_type),
+    trim(tc.constraint_name),
+    trim(kcu.column_name)
+FROM
+    information_schema.table_constraints tc
 JOIN
-    pg_namespace AS n ON c.relnamespace = n.oid
+    information_schema.key_column_usage kcu
+    ON kcu.constraint_name = tc.constraint_name
+   AND kcu.table_schema = tc.table_schema
 WHERE
-    n.nspname NOT IN ('information_schema', 'pg_catalog');
-`
+    tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+ORDER BY
+    tc.table_schema, tc.table_name, tc.constraint_name, kcu.ordinal_position;
+`)
+	if err != nil {
+		return fmt.Errorf("could not introspect key constraints: %w", err)
+	}
+
+	unique := make(map[string][]string)
+	var uniqueOrder []string
+
+	for {
+		row, err := rows.Next()
+		if row == nil {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		schemaName, tableName := row[0].(string), row[1].(string)
+		constraintType, constraintName, column := row[2].(string), row[3].(string), row[4].(string)
+		t := tableOf(schemaName, tableName)
+
+		if constraintType == "PRIMARY KEY" {
+			t.PrimaryKey = append(t.PrimaryKey, column)
+			continue
+		}
 
-	rows, err := c.Query(query)
+		key := schemaName + "." + tableName + "." + constraintName
+		if _, ok := unique[key]; !ok {
+			uniqueOrder = append(uniqueOrder, key)
+		}
+		unique[key] = append(unique[key], column)
+	}
+
+	for _, key := range uniqueOrder {
+		parts := strings.SplitN(key, ".", 3)
+		t := tableOf(parts[0], parts[1])
+		t.Unique = append(t.Unique, unique[key])
+	}
+
+	return nil
+}
+
+// loadForeignKeys fills in each table's ForeignKeys. Referencing and
+// referenced columns are correlated by position within their respective
+// constraints via key_column_usage.position_in_unique_constraint rather
+// than by joining key_column_usage straight to constraint_column_usage,
+// which cross-joins every referencing column with every referenced
+// column of a composite key. referential_constraints also gives the
+// referenced table's own schema, since it isn't necessarily the same as
+// the referencing table's.
+func (c *RedshiftClient) loadForeignKeys(ctx context.Context, tableOf func(schemaName, tableName string) *schema.Table) error {
+	rows, err := c.Query(ctx, `
+	SELECT
+    trim(tc.table_schema),
+    trim(tc.table_name),
+    trim(tc.constraint_name),
+    trim(kcu.column_name),
+    trim(ref_kcu.table_schema),
+    trim(ref_kcu.table_name),
+    trim(ref_kcu.column_name)
+FROM
+    information_schema.table_constraints tc
+JOIN
+    information_schema.key_column_usage kcu
+    ON kcu.constraint_name = tc.constraint_name
+   AND kcu.table_schema = tc.table_schema
+JOIN
+    information_schema.referential_constraints rc
+    ON rc.constraint_name = tc.constraint_name
+   AND rc.constraint_schema = tc.table_schema
+JOIN
+    information_schema.key_column_usage ref_kcu
+    ON ref_kcu.constraint_name = rc.unique_constraint_name
+   AND ref_kcu.table_schema = rc.unique_constraint_schema
+   AND ref_kcu.ordinal_position = kcu.position_in_unique_constraint
+WHERE
+    tc.constraint_type = 'FOREIGN KEY'
+ORDER BY
+    tc.table_schema, tc.table_name, tc.constraint_name, kcu.ordinal_position;
+`)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("could not introspect foreign keys: %w", err)
 	}
 
-	return fetchPsqlLayouts(rows, redshiftClient)
+	fks := make(map[string]*schema.ForeignKey)
+	var order []string
+	owners := make(map[string]*schema.Table)
+
+	for {
+		row, err := rows.Next()
+		if row == nil {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		schemaName, tableName, constraintName := row[0].(string), row[1].(string), row[2].(string)
+		column, refSchema, refTable, refColumn := row[3].(string), row[4].(string), row[5].(string), row[6].(string)
+
+		key := schemaName + "." + tableName + "." + constraintName
+		fk, ok := fks[key]
+		if !ok {
+			fk = &schema.ForeignKey{Name: constraintName, RefSchema: refSchema, RefTable: refTable}
+			fks[key] = fk
+			owners[key] = tableOf(schemaName, tableName)
+			order = append(order, key)
+		}
+		fk.Columns = append(fk.Columns, column)
+		fk.RefColumns = append(fk.RefColumns, refColumn)
+	}
+
+	for _, key := range order {
+		owners[key].ForeignKeys = append(owners[key].ForeignKeys, *fks[key])
+	}
+
+	return nil
+}
+
+// loadIndexes fills in each table's Indexes from pg_indexes, which
+// Redshift exposes for postgres compatibility even though it doesn't use
+// traditional B-tree indexes internally.
+func (c *RedshiftClient) loadIndexes(ctx context.Context, tableOf func(schemaName, tableName string) *schema.Table) error {
+	rows, err := c.Query(ctx, `
+	SELECT
+    trim(schemaname),
+    trim(tablename),
+    trim(indexname),
+    trim(indexdef)
+FROM
+    pg_indexes
+WHERE
+    schemaname NOT IN ('information_schema', 'pg_catalog');
+`)
+	if err != nil {
+		return fmt.Errorf("could not introspect indexes: %w", err)
+	}
+
+	for {
+		row, err := rows.Next()
+		if row == nil {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		schemaName, tableName := row[0].(string), row[1].(string)
+		indexName, indexDef := row[2].(string), row[3].(string)
+
+		columns, unique := parseIndexDef(indexDef)
+		t := tableOf(schemaName, tableName)
+		t.Indexes = append(t.Indexes, schema.Index{
+			Name:    indexName,
+			Columns: columns,
+			Unique:  unique,
+		})
+	}
+
+	return nil
+}
+
+// parseIndexDef extracts the indexed columns and uniqueness out of a
+// pg_indexes.indexdef string, e.g.
+// "CREATE UNIQUE INDEX foo ON schema.bar USING btree (a, b)".
+func parseIndexDef(indexDef string) (columns []string, unique bool) {
+	unique = strings.Contains(indexDef, "UNIQUE")
+
+	open := strings.LastIndex(indexDef, "(")
+	shut := strings.LastIndex(indexDef, ")")
+	if open == -1 || shut == -1 || shut < open {
+		return nil, unique
+	}
+
+	for _, col := range strings.Split(indexDef[open+1:shut], ",") {
+		columns = append(columns, strings.TrimSpace(col))
+	}
+
+	return columns, unique
 }
 
 // fetchPsqlLayouts fetches the layout from the postgres database.
@@ -153,10 +560,16 @@ func fetchPsqlLayouts(rows models.IterResult, dbType string) ([]models.Layout, e
 // getLayoutType returns the layout type based on the string.
 func getLayoutType(typ string) models.LayoutType {
 	switch typ {
-	case "TABLE":
+	case "TABLE", "BASE TABLE":
 		return models.LayoutTypeTable
 	case "VIEW":
 		return models.LayoutTypeView
+	case "EXTERNAL TABLE":
+		return models.LayoutTypeExternalTable
+	case "MATERIALIZED VIEW":
+		return models.LayoutTypeMaterializedView
+	case "SHARED TABLE":
+		return models.LayoutTypeSharedTable
 	default:
 		return models.LayoutTypeNone
 	}
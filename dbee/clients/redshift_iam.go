@@ -0,0 +1,142 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+)
+
+const redshiftIAMScheme = "redshift-iam"
+
+// redshiftCredentialsAPI is the subset of the AWS Redshift client needed
+// for IAM authentication. It exists so tests can inject a mock instead of
+// talking to AWS.
+type redshiftCredentialsAPI interface {
+	GetClusterCredentials(ctx context.Context, params *redshift.GetClusterCredentialsInput, optFns ...func(*redshift.Options)) (*redshift.GetClusterCredentialsOutput, error)
+	DescribeClusters(ctx context.Context, params *redshift.DescribeClustersInput, optFns ...func(*redshift.Options)) (*redshift.DescribeClustersOutput, error)
+}
+
+// redshiftIAMAuth obtains and caches short-lived IAM credentials for a
+// Redshift cluster, refreshing them on refreshCreds once they're about to
+// expire so long-lived Neovim sessions don't get disconnected after the
+// default 15 minute credential lifetime.
+type redshiftIAMAuth struct {
+	api redshiftCredentialsAPI
+
+	clusterID string
+	dbName    string
+	dbUser    string
+
+	mu        sync.Mutex
+	host      string
+	port      string
+	password  string
+	expiresAt time.Time
+}
+
+// parseRedshiftIAMURL turns a "redshift-iam://<cluster-id>.<region>/<db>?DbUser=...&Profile=..."
+// URL into a redshiftIAMAuth, loading AWS config for the given profile (or
+// the default credential chain if none is set).
+func parseRedshiftIAMURL(ctx context.Context, u *url.URL) (*redshiftIAMAuth, error) {
+	parts := strings.SplitN(u.Host, ".", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("redshift-iam url host %q must be <cluster-id>.<region>", u.Host)
+	}
+	clusterID, region := parts[0], parts[1]
+
+	dbName := strings.TrimPrefix(u.Path, "/")
+	if dbName == "" {
+		return nil, fmt.Errorf("redshift-iam url is missing a database name")
+	}
+
+	q := u.Query()
+	dbUser := q.Get("DbUser")
+	if dbUser == "" {
+		return nil, fmt.Errorf("redshift-iam url is missing the DbUser query parameter")
+	}
+
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(region)}
+	if profile := q.Get("Profile"); profile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(profile))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load aws config: %w", err)
+	}
+
+	return &redshiftIAMAuth{
+		api:       redshift.NewFromConfig(cfg),
+		clusterID: clusterID,
+		dbName:    dbName,
+		dbUser:    dbUser,
+	}, nil
+}
+
+// dsn returns a standard postgres DSN built from the cached credentials,
+// calling refreshCreds first if they're missing or near expiry.
+func (a *redshiftIAMAuth) dsn(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if time.Now().Add(time.Minute).Before(a.expiresAt) {
+		return a.buildDSN(), nil
+	}
+
+	if err := a.refreshCreds(ctx); err != nil {
+		return "", err
+	}
+	return a.buildDSN(), nil
+}
+
+// refreshCreds fetches the cluster endpoint (on first use) and a fresh
+// DbUser/DbPassword pair via GetClusterCredentials. Callers must hold a.mu.
+func (a *redshiftIAMAuth) refreshCreds(ctx context.Context) error {
+	if a.host == "" {
+		described, err := a.api.DescribeClusters(ctx, &redshift.DescribeClustersInput{
+			ClusterIdentifier: aws.String(a.clusterID),
+		})
+		if err != nil {
+			return fmt.Errorf("could not describe cluster %q: %w", a.clusterID, err)
+		}
+		if len(described.Clusters) == 0 || described.Clusters[0].Endpoint == nil {
+			return fmt.Errorf("cluster %q has no endpoint yet", a.clusterID)
+		}
+		endpoint := described.Clusters[0].Endpoint
+		a.host = aws.ToString(endpoint.Address)
+		a.port = fmt.Sprintf("%d", aws.ToInt32(endpoint.Port))
+	}
+
+	creds, err := a.api.GetClusterCredentials(ctx, &redshift.GetClusterCredentialsInput{
+		ClusterIdentifier: aws.String(a.clusterID),
+		DbName:            aws.String(a.dbName),
+		DbUser:            aws.String(a.dbUser),
+		AutoCreate:        aws.Bool(false),
+	})
+	if err != nil {
+		return fmt.Errorf("could not get cluster credentials: %w", err)
+	}
+
+	a.password = aws.ToString(creds.DbPassword)
+	a.expiresAt = aws.ToTime(creds.Expiration)
+
+	return nil
+}
+
+func (a *redshiftIAMAuth) buildDSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(a.dbUser, a.password),
+		Host:     fmt.Sprintf("%s:%s", a.host, a.port),
+		Path:     "/" + a.dbName,
+		RawQuery: "sslmode=require",
+	}
+	return u.String()
+}
@@ -0,0 +1,133 @@
+package clients
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/redshift/types"
+)
+
+// fakeRedshiftAPI is a redshiftCredentialsAPI stub that avoids talking to
+// AWS, counting calls so tests can assert on caching/refresh behavior.
+type fakeRedshiftAPI struct {
+	describeCalls int
+	credsCalls    int
+
+	password  string
+	expiresIn time.Duration
+}
+
+func (f *fakeRedshiftAPI) DescribeClusters(ctx context.Context, params *redshift.DescribeClustersInput, optFns ...func(*redshift.Options)) (*redshift.DescribeClustersOutput, error) {
+	f.describeCalls++
+	return &redshift.DescribeClustersOutput{
+		Clusters: []types.Cluster{
+			{
+				Endpoint: &types.Endpoint{
+					Address: aws.String("cluster.abc123.us-east-1.redshift.amazonaws.com"),
+					Port:    aws.Int32(5439),
+				},
+			},
+		},
+	}, nil
+}
+
+func (f *fakeRedshiftAPI) GetClusterCredentials(ctx context.Context, params *redshift.GetClusterCredentialsInput, optFns ...func(*redshift.Options)) (*redshift.GetClusterCredentialsOutput, error) {
+	f.credsCalls++
+	return &redshift.GetClusterCredentialsOutput{
+		DbUser:     params.DbUser,
+		DbPassword: aws.String(f.password),
+		Expiration: aws.Time(time.Now().Add(f.expiresIn)),
+	}, nil
+}
+
+func TestRedshiftIAMAuth_DSN(t *testing.T) {
+	api := &fakeRedshiftAPI{password: "s3cr3t", expiresIn: 15 * time.Minute}
+	auth := &redshiftIAMAuth{
+		api:       api,
+		clusterID: "my-cluster",
+		dbName:    "analytics",
+		dbUser:    "reporting",
+	}
+
+	dsn, err := auth.dsn(context.Background())
+	if err != nil {
+		t.Fatalf("dsn: %v", err)
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("parsing returned dsn: %v", err)
+	}
+	if u.Host != "cluster.abc123.us-east-1.redshift.amazonaws.com:5439" {
+		t.Errorf("host = %q, want the described cluster endpoint", u.Host)
+	}
+	if pw, _ := u.User.Password(); pw != "s3cr3t" {
+		t.Errorf("password = %q, want %q", pw, "s3cr3t")
+	}
+	if api.describeCalls != 1 || api.credsCalls != 1 {
+		t.Fatalf("describeCalls=%d credsCalls=%d, want 1 and 1", api.describeCalls, api.credsCalls)
+	}
+}
+
+func TestRedshiftIAMAuth_CachesUntilNearExpiry(t *testing.T) {
+	api := &fakeRedshiftAPI{password: "first", expiresIn: time.Hour}
+	auth := &redshiftIAMAuth{api: api, clusterID: "c", dbName: "d", dbUser: "u"}
+
+	if _, err := auth.dsn(context.Background()); err != nil {
+		t.Fatalf("dsn: %v", err)
+	}
+	if _, err := auth.dsn(context.Background()); err != nil {
+		t.Fatalf("dsn: %v", err)
+	}
+
+	if api.credsCalls != 1 {
+		t.Errorf("credsCalls = %d, want 1 (second call should hit the cache)", api.credsCalls)
+	}
+	if api.describeCalls != 1 {
+		t.Errorf("describeCalls = %d, want 1 (cluster endpoint doesn't change)", api.describeCalls)
+	}
+}
+
+func TestRedshiftIAMAuth_RefreshesNearExpiry(t *testing.T) {
+	api := &fakeRedshiftAPI{password: "stale", expiresIn: 30 * time.Second}
+	auth := &redshiftIAMAuth{api: api, clusterID: "c", dbName: "d", dbUser: "u"}
+
+	if _, err := auth.dsn(context.Background()); err != nil {
+		t.Fatalf("dsn: %v", err)
+	}
+
+	api.password = "fresh"
+	dsn, err := auth.dsn(context.Background())
+	if err != nil {
+		t.Fatalf("dsn: %v", err)
+	}
+
+	u, _ := url.Parse(dsn)
+	if pw, _ := u.User.Password(); pw != "fresh" {
+		t.Errorf("password = %q, want refreshed password %q", pw, "fresh")
+	}
+	if api.credsCalls != 2 {
+		t.Errorf("credsCalls = %d, want 2 (credentials were within a minute of expiry)", api.credsCalls)
+	}
+}
+
+func TestParseRedshiftIAMURL(t *testing.T) {
+	u, err := url.Parse("redshift-iam://my-cluster.us-east-1/analytics?DbUser=reporting&Profile=prod")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+
+	auth, err := parseRedshiftIAMURL(context.Background(), u)
+	if err != nil {
+		t.Fatalf("parseRedshiftIAMURL: %v", err)
+	}
+
+	if auth.clusterID != "my-cluster" || auth.dbName != "analytics" || auth.dbUser != "reporting" {
+		t.Errorf("got clusterID=%q dbName=%q dbUser=%q, want my-cluster/analytics/reporting",
+			auth.clusterID, auth.dbName, auth.dbUser)
+	}
+}
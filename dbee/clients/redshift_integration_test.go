@@ -0,0 +1,62 @@
+//go:build integration
+
+package clients
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/kndndrj/nvim-dbee/dbee/clients/clienttest"
+	"github.com/kndndrj/nvim-dbee/dbee/conn"
+)
+
+// TestRedshiftClient_Conformance runs the shared clienttest suite against
+// a plain postgres container, since there's no public Redshift docker
+// image - the wire protocol RedshiftClient relies on for Query/Close is
+// the same one postgres exposes. Layout is skipped: it queries Redshift's
+// SVV_* system views, which don't exist on plain postgres, so that check
+// only runs against a genuine Redshift cluster.
+func TestRedshiftClient_Conformance(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "dbee",
+				"POSTGRES_PASSWORD": "dbee",
+				"POSTGRES_DB":       "dbee",
+			},
+			WaitingFor: wait.ForListeningPort("5432/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("starting postgres container: %v", err)
+	}
+	defer container.Terminate(ctx) //nolint:errcheck
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "5432")
+	if err != nil {
+		t.Fatalf("container port: %v", err)
+	}
+
+	dsn := fmt.Sprintf("postgres://dbee:dbee@%s:%s/dbee?sslmode=disable", host, port.Port())
+
+	if err := clienttest.Seed(ctx, dsn); err != nil {
+		t.Fatalf("seeding fixture: %v", err)
+	}
+
+	clienttest.Suite(t, func(ctx context.Context, dsn string) (conn.Client, error) {
+		return NewRedshift(ctx, dsn)
+	}, dsn, clienttest.Options{SkipLayout: true})
+}
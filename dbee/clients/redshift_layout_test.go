@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/kndndrj/nvim-dbee/dbee/models"
+)
+
+// stubIterResult is a fixed, in-memory models.IterResult used to unit
+// test fetchPsqlLayouts/getLayoutType without a real connection.
+type stubIterResult struct {
+	rows []models.Row
+	i    int
+}
+
+func (s *stubIterResult) Next() (models.Row, error) {
+	if s.i >= len(s.rows) {
+		return nil, nil
+	}
+	row := s.rows[s.i]
+	s.i++
+	return row, nil
+}
+
+func (s *stubIterResult) SetCallback(func()) {}
+
+func TestGetLayoutType(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want models.LayoutType
+	}{
+		{"TABLE", models.LayoutTypeTable},
+		{"BASE TABLE", models.LayoutTypeTable},
+		{"VIEW", models.LayoutTypeView},
+		{"EXTERNAL TABLE", models.LayoutTypeExternalTable},
+		{"MATERIALIZED VIEW", models.LayoutTypeMaterializedView},
+		{"SHARED TABLE", models.LayoutTypeSharedTable},
+		{"SOMETHING ELSE", models.LayoutTypeNone},
+	}
+
+	for _, tt := range tests {
+		if got := getLayoutType(tt.typ); got != tt.want {
+			t.Errorf("getLayoutType(%q) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestFetchPsqlLayouts_Redshift(t *testing.T) {
+	rows := &stubIterResult{
+		rows: []models.Row{
+			{"public", "widgets", "BASE TABLE"},
+			{"public", "widgets_view", "VIEW"},
+			{"spectrum", "events", "EXTERNAL TABLE"},
+			{"public", "daily_totals", "MATERIALIZED VIEW"},
+			{"shared", "orders", "SHARED TABLE"},
+		},
+	}
+
+	layout, err := fetchPsqlLayouts(rows, redshiftClient)
+	if err != nil {
+		t.Fatalf("fetchPsqlLayouts: %v", err)
+	}
+
+	schemas := make(map[string]models.Layout)
+	for _, s := range layout {
+		schemas[s.Name] = s
+	}
+
+	checks := []struct {
+		schema, table string
+		want          models.LayoutType
+	}{
+		{"public", "widgets", models.LayoutTypeTable},
+		{"public", "widgets_view", models.LayoutTypeView},
+		{"spectrum", "events", models.LayoutTypeExternalTable},
+		{"public", "daily_totals", models.LayoutTypeMaterializedView},
+		{"shared", "orders", models.LayoutTypeSharedTable},
+	}
+
+	for _, c := range checks {
+		s, ok := schemas[c.schema]
+		if !ok {
+			t.Fatalf("schema %q missing from layout", c.schema)
+		}
+
+		var got *models.LayoutType
+		for _, child := range s.Children {
+			if child.Name == c.table {
+				typ := child.Type
+				got = &typ
+			}
+		}
+		if got == nil {
+			t.Errorf("table %s.%s missing from layout", c.schema, c.table)
+			continue
+		}
+		if *got != c.want {
+			t.Errorf("table %s.%s type = %v, want %v", c.schema, c.table, *got, c.want)
+		}
+	}
+}
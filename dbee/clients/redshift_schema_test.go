@@ -0,0 +1,46 @@
+package clients
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIndexDef(t *testing.T) {
+	tests := []struct {
+		name       string
+		indexDef   string
+		wantCols   []string
+		wantUnique bool
+	}{
+		{
+			name:       "unique single column",
+			indexDef:   "CREATE UNIQUE INDEX widgets_pkey ON public.widgets USING btree (id)",
+			wantCols:   []string{"id"},
+			wantUnique: true,
+		},
+		{
+			name:       "non-unique multi column",
+			indexDef:   "CREATE INDEX widgets_name_created_idx ON public.widgets USING btree (name, created_at)",
+			wantCols:   []string{"name", "created_at"},
+			wantUnique: false,
+		},
+		{
+			name:       "malformed def",
+			indexDef:   "CREATE INDEX widgets_idx ON public.widgets",
+			wantCols:   nil,
+			wantUnique: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, unique := parseIndexDef(tt.indexDef)
+			if !reflect.DeepEqual(cols, tt.wantCols) {
+				t.Errorf("columns = %v, want %v", cols, tt.wantCols)
+			}
+			if unique != tt.wantUnique {
+				t.Errorf("unique = %v, want %v", unique, tt.wantUnique)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/kndndrj/nvim-dbee/dbee/schema"
+)
+
+// SchemaClient is implemented by clients that can introspect their
+// connection into a normalized schema.Schema, which can then be dumped,
+// diffed against another connection, or used as the target of a
+// migration run.
+type SchemaClient interface {
+	Schema(ctx context.Context) (*schema.Schema, error)
+}
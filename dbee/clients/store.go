@@ -0,0 +1,47 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kndndrj/nvim-dbee/dbee/conn"
+)
+
+// RegisterFn constructs a conn.Client for the given connection url. Every
+// driver in this package registers one under its own name in init().
+type RegisterFn func(ctx context.Context, url string) (conn.Client, error)
+
+// registry is a concurrency-safe lookup of RegisterFn by driver name, used
+// to wire connection types up to the lua frontend.
+type registry struct {
+	mu  sync.Mutex
+	fns map[string]RegisterFn
+}
+
+// Store is the global registry every client's init() registers into.
+var Store = &registry{fns: make(map[string]RegisterFn)}
+
+// Register adds fn under name. It returns an error if name is already
+// registered.
+func (r *registry) Register(name string, fn RegisterFn) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.fns[name]; ok {
+		return fmt.Errorf("client %q is already registered", name)
+	}
+	r.fns[name] = fn
+	return nil
+}
+
+// New looks up name and calls its RegisterFn with url.
+func (r *registry) New(ctx context.Context, name, url string) (conn.Client, error) {
+	r.mu.Lock()
+	fn, ok := r.fns[name]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no client registered for %q", name)
+	}
+	return fn(ctx, url)
+}
@@ -0,0 +1,22 @@
+// Package conn defines the interface every registered database driver
+// implements, so the rest of dbee can treat Postgres, Redshift, MySQL,
+// SQLite, etc. uniformly.
+package conn
+
+import (
+	"context"
+
+	"github.com/kndndrj/nvim-dbee/dbee/models"
+)
+
+// Client is implemented by every database driver registered with
+// clients.Store.
+type Client interface {
+	// Query runs query and returns the results as an IterResult. It's
+	// aborted if ctx is cancelled or its deadline is exceeded.
+	Query(ctx context.Context, query string) (models.IterResult, error)
+	// Layout returns the schema tree for the connected database.
+	Layout(ctx context.Context) ([]models.Layout, error)
+	// Close closes the connection.
+	Close()
+}
@@ -0,0 +1,40 @@
+// Package models holds the value types shared between dbee's clients and
+// the lua frontend: query results and the schema tree shown in the
+// drawer, along with their supporting enums.
+package models
+
+// Row is a single row of query results, one value per selected column.
+type Row []any
+
+// IterResult iterates over a query's results one row at a time.
+type IterResult interface {
+	// Next returns the next row, or (nil, nil) once exhausted.
+	Next() (Row, error)
+	// SetCallback registers a function to run once Next returns (nil, nil)
+	// or an error - clients use this to release the connection the
+	// result came from.
+	SetCallback(func())
+}
+
+// LayoutType describes what kind of object a Layout node represents.
+type LayoutType int
+
+const (
+	LayoutTypeNone LayoutType = iota
+	LayoutTypeTable
+	LayoutTypeView
+	LayoutTypeExternalTable
+	LayoutTypeMaterializedView
+	LayoutTypeSharedTable
+)
+
+// Layout is a single node in the schema tree shown in the lua frontend -
+// a database, a schema, or a table/view nested under one. Ordering of
+// Children is left to the frontend.
+type Layout struct {
+	Name     string
+	Schema   string
+	Database string
+	Type     LayoutType
+	Children []Layout
+}
@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diff compares two schemas and returns the ordered list of DDL statements
+// that would bring "from" in sync with "to": a CREATE TABLE (columns and
+// primary key) for each table missing from "from", an ADD COLUMN for each
+// missing column, and an ALTER COLUMN ... TYPE for each column whose type
+// differs. Nullability, defaults, unique constraints, foreign keys and
+// indexes are not compared, and nothing is dropped - dropping tables or
+// columns is left to the caller, since it's rarely what you want to do
+// automatically.
+func Diff(from, to *Schema) ([]string, error) {
+	var stmts []string
+
+	for _, table := range to.Tables {
+		existing := from.find(table.Schema, table.Name)
+		if existing == nil {
+			stmts = append(stmts, createTableStmt(table))
+			continue
+		}
+
+		stmts = append(stmts, diffColumns(table, *existing)...)
+	}
+
+	return stmts, nil
+}
+
+func createTableStmt(t Table) string {
+	var cols []string
+	for _, c := range t.Columns {
+		cols = append(cols, columnDef(c))
+	}
+	if len(t.PrimaryKey) > 0 {
+		cols = append(cols, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(t.PrimaryKey, ", ")))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s.%s (\n\t%s\n);", t.Schema, t.Name, strings.Join(cols, ",\n\t"))
+}
+
+func diffColumns(want, have Table) []string {
+	var stmts []string
+
+	haveCols := make(map[string]Column)
+	for _, c := range have.Columns {
+		haveCols[c.Name] = c
+	}
+
+	for _, c := range want.Columns {
+		existing, ok := haveCols[c.Name]
+		if !ok {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN %s;", want.Schema, want.Name, columnDef(c)))
+			continue
+		}
+		if existing.Type != c.Type {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s.%s ALTER COLUMN %s TYPE %s;", want.Schema, want.Name, c.Name, c.Type))
+		}
+	}
+
+	return stmts
+}
+
+func columnDef(c Column) string {
+	def := fmt.Sprintf("%s %s", c.Name, c.Type)
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	return def
+}
@@ -0,0 +1,225 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const migrationsTable = "schema_migrations"
+
+// Migration is a single versioned migration step, loaded from a pair of
+// "<version>_<name>.up.sql" / "<version>_<name>.down.sql" files.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// LoadMigrations reads all "*.up.sql"/"*.down.sql" file pairs from dir and
+// returns them sorted by version, ascending.
+func LoadMigrations(dir fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, fmt.Errorf("could not read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		name := e.Name()
+		direction := ""
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		version, rest, err := parseMigrationName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := fs.ReadFile(dir, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %q: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: rest}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// parseMigrationName splits "<version>_<name>.<up|down>.sql" into its
+// version and name parts.
+func parseMigrationName(filename string) (int64, string, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(filename, ".up.sql"), ".down.sql")
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("migration filename %q does not match <version>_<name>", filename)
+	}
+
+	version, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("migration filename %q has a non-numeric version: %w", filename, err)
+	}
+
+	return version, parts[1], nil
+}
+
+// Placeholders renders the nth (1-based) bind parameter in a driver's
+// query syntax.
+type Placeholders func(n int) string
+
+// DollarPlaceholders renders $1, $2, ... as used by postgres/pgx.
+func DollarPlaceholders(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// QuestionPlaceholders renders every placeholder as ?, as used by MySQL.
+func QuestionPlaceholders(int) string {
+	return "?"
+}
+
+// Migrator applies Migrations against a *sql.DB, tracking which versions
+// have already run in the schema_migrations table.
+type Migrator struct {
+	db           *sql.DB
+	placeholders Placeholders
+}
+
+// NewMigrator creates a Migrator bound to db. placeholders selects the
+// bind-parameter syntax for db's driver, e.g. DollarPlaceholders for
+// postgres/pgx or QuestionPlaceholders for MySQL.
+func NewMigrator(db *sql.DB, placeholders Placeholders) *Migrator {
+	return &Migrator{db: db, placeholders: placeholders}
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, name TEXT NOT NULL)`,
+		migrationsTable,
+	))
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]bool, error) {
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf(`SELECT version FROM %s`, migrationsTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[int64]bool)
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		versions[v] = true
+	}
+	return versions, rows.Err()
+}
+
+// Up applies every migration in migrations that hasn't already been
+// recorded in schema_migrations, in ascending version order.
+func (m *Migrator) Up(ctx context.Context, migrations []Migration) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return fmt.Errorf("could not prepare %s table: %w", migrationsTable, err)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return fmt.Errorf("could not read applied migrations: %w", err)
+	}
+
+	for _, mg := range migrations {
+		if applied[mg.Version] {
+			continue
+		}
+
+		if err := m.runInTx(ctx, mg.Up, mg.Version, mg.Name, true); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", mg.Version, mg.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Down reverts the most recently applied migration found in migrations.
+func (m *Migrator) Down(ctx context.Context, migrations []Migration) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return fmt.Errorf("could not prepare %s table: %w", migrationsTable, err)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return fmt.Errorf("could not read applied migrations: %w", err)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		mg := migrations[i]
+		if !applied[mg.Version] {
+			continue
+		}
+
+		return m.runInTx(ctx, mg.Down, mg.Version, mg.Name, false)
+	}
+
+	return nil
+}
+
+func (m *Migrator) runInTx(ctx context.Context, stmt string, version int64, name string, up bool) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+
+	if up {
+		stmt := fmt.Sprintf(`INSERT INTO %s (version, name) VALUES (%s, %s)`,
+			migrationsTable, m.placeholders(1), m.placeholders(2))
+		if _, err := tx.ExecContext(ctx, stmt, version, name); err != nil {
+			return err
+		}
+	} else {
+		stmt := fmt.Sprintf(`DELETE FROM %s WHERE version = %s`, migrationsTable, m.placeholders(1))
+		if _, err := tx.ExecContext(ctx, stmt, version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,59 @@
+// Package schema provides database introspection, diffing and migration
+// primitives shared by the clients in dbee/clients. It lets nvim-dbee act
+// as more than a query runner: connections can be introspected into a
+// normalized Schema, compared against one another, and brought in sync
+// via generated or file-based migrations.
+package schema
+
+// Schema is a normalized representation of a database's structure,
+// independent of the SQL dialect that produced it.
+type Schema struct {
+	Tables []Table
+}
+
+// Table describes a single table or view and its constraints.
+type Table struct {
+	Schema      string
+	Name        string
+	Columns     []Column
+	PrimaryKey  []string
+	Unique      [][]string
+	ForeignKeys []ForeignKey
+	Indexes     []Index
+}
+
+// Column describes a single column of a Table.
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+}
+
+// ForeignKey describes a foreign key constraint from one table's columns
+// to another table's columns.
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefSchema  string
+	RefTable   string
+	RefColumns []string
+}
+
+// Index describes an index on a table, unique or not, including one that
+// backs a primary key.
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// find returns the table with the given schema/name from s, or nil.
+func (s *Schema) find(schemaName, name string) *Table {
+	for i := range s.Tables {
+		if s.Tables[i].Schema == schemaName && s.Tables[i].Name == name {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}